@@ -0,0 +1,223 @@
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// NewClientset builds a Kubernetes clientset from raw kubeconfig bytes,
+// as mounted from a dagger.Secret.
+func NewClientset(kubeconfig []byte) (*kubernetes.Clientset, error) {
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+	return kubernetes.NewForConfig(restConfig)
+}
+
+// Apply applies the rendered manifests to the cluster, creating objects
+// that don't exist yet and updating ones that do.
+func Apply(ctx context.Context, clientset *kubernetes.Clientset, namespace string, manifests *Manifests) (string, error) {
+	var applied []string
+
+	deployments := clientset.AppsV1().Deployments(namespace)
+	if _, err := deployments.Get(ctx, manifests.Deployment.Name, metav1.GetOptions{}); apierrors.IsNotFound(err) {
+		if _, err := deployments.Create(ctx, manifests.Deployment, metav1.CreateOptions{}); err != nil {
+			return "", fmt.Errorf("failed to create deployment: %w", err)
+		}
+		applied = append(applied, "created deployment/"+manifests.Deployment.Name)
+	} else if err != nil {
+		return "", fmt.Errorf("failed to get deployment: %w", err)
+	} else {
+		if _, err := deployments.Update(ctx, manifests.Deployment, metav1.UpdateOptions{}); err != nil {
+			return "", fmt.Errorf("failed to update deployment: %w", err)
+		}
+		applied = append(applied, "updated deployment/"+manifests.Deployment.Name)
+	}
+
+	services := clientset.CoreV1().Services(namespace)
+	if live, err := services.Get(ctx, manifests.Service.Name, metav1.GetOptions{}); apierrors.IsNotFound(err) {
+		if _, err := services.Create(ctx, manifests.Service, metav1.CreateOptions{}); err != nil {
+			return "", fmt.Errorf("failed to create service: %w", err)
+		}
+		applied = append(applied, "created service/"+manifests.Service.Name)
+	} else if err != nil {
+		return "", fmt.Errorf("failed to get service: %w", err)
+	} else {
+		manifests.Service.ResourceVersion = live.ResourceVersion
+		manifests.Service.Spec.ClusterIP = live.Spec.ClusterIP
+		if _, err := services.Update(ctx, manifests.Service, metav1.UpdateOptions{}); err != nil {
+			return "", fmt.Errorf("failed to update service: %w", err)
+		}
+		applied = append(applied, "updated service/"+manifests.Service.Name)
+	}
+
+	hpas := clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace)
+	if _, err := hpas.Get(ctx, manifests.HPA.Name, metav1.GetOptions{}); apierrors.IsNotFound(err) {
+		if _, err := hpas.Create(ctx, manifests.HPA, metav1.CreateOptions{}); err != nil {
+			return "", fmt.Errorf("failed to create HPA: %w", err)
+		}
+		applied = append(applied, "created hpa/"+manifests.HPA.Name)
+	} else if err != nil {
+		return "", fmt.Errorf("failed to get HPA: %w", err)
+	} else {
+		if _, err := hpas.Update(ctx, manifests.HPA, metav1.UpdateOptions{}); err != nil {
+			return "", fmt.Errorf("failed to update HPA: %w", err)
+		}
+		applied = append(applied, "updated hpa/"+manifests.HPA.Name)
+	}
+
+	networkPolicies := clientset.NetworkingV1().NetworkPolicies(namespace)
+	if live, err := networkPolicies.Get(ctx, manifests.NetworkPolicy.Name, metav1.GetOptions{}); apierrors.IsNotFound(err) {
+		if _, err := networkPolicies.Create(ctx, manifests.NetworkPolicy, metav1.CreateOptions{}); err != nil {
+			return "", fmt.Errorf("failed to create network policy: %w", err)
+		}
+		applied = append(applied, "created networkpolicy/"+manifests.NetworkPolicy.Name)
+	} else if err != nil {
+		return "", fmt.Errorf("failed to get network policy: %w", err)
+	} else {
+		manifests.NetworkPolicy.ResourceVersion = live.ResourceVersion
+		if _, err := networkPolicies.Update(ctx, manifests.NetworkPolicy, metav1.UpdateOptions{}); err != nil {
+			return "", fmt.Errorf("failed to update network policy: %w", err)
+		}
+		applied = append(applied, "updated networkpolicy/"+manifests.NetworkPolicy.Name)
+	}
+
+	if manifests.Ingress != nil {
+		ingresses := clientset.NetworkingV1().Ingresses(namespace)
+		if live, err := ingresses.Get(ctx, manifests.Ingress.Name, metav1.GetOptions{}); apierrors.IsNotFound(err) {
+			if _, err := ingresses.Create(ctx, manifests.Ingress, metav1.CreateOptions{}); err != nil {
+				return "", fmt.Errorf("failed to create ingress: %w", err)
+			}
+			applied = append(applied, "created ingress/"+manifests.Ingress.Name)
+		} else if err != nil {
+			return "", fmt.Errorf("failed to get ingress: %w", err)
+		} else {
+			manifests.Ingress.ResourceVersion = live.ResourceVersion
+			if _, err := ingresses.Update(ctx, manifests.Ingress, metav1.UpdateOptions{}); err != nil {
+				return "", fmt.Errorf("failed to update ingress: %w", err)
+			}
+			applied = append(applied, "updated ingress/"+manifests.Ingress.Name)
+		}
+	}
+
+	return strings.Join(applied, "\n"), nil
+}
+
+// Plan compares the rendered manifests against the live cluster and
+// returns a human-readable diff without changing anything. It backs the
+// plan mode of deployctl and DeployFromSpec; Pipeline does not call it and
+// has no dry-run mode. The HPA is only checked for existence; its spec is
+// not compared.
+func Plan(ctx context.Context, clientset *kubernetes.Clientset, namespace string, manifests *Manifests) (string, error) {
+	var lines []string
+
+	deployments := clientset.AppsV1().Deployments(namespace)
+	live, err := deployments.Get(ctx, manifests.Deployment.Name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		lines = append(lines, fmt.Sprintf("+ deployment/%s will be created (image=%s, replicas=%d)",
+			manifests.Deployment.Name, manifests.Deployment.Spec.Template.Spec.Containers[0].Image, *manifests.Deployment.Spec.Replicas))
+	case err != nil:
+		return "", fmt.Errorf("failed to get deployment: %w", err)
+	default:
+		lines = append(lines, diffDeployment(live, manifests.Deployment))
+	}
+
+	services := clientset.CoreV1().Services(namespace)
+	liveService, err := services.Get(ctx, manifests.Service.Name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		lines = append(lines, fmt.Sprintf("+ service/%s will be created", manifests.Service.Name))
+	case err != nil:
+		return "", fmt.Errorf("failed to get service: %w", err)
+	default:
+		lines = append(lines, diffService(liveService, manifests.Service))
+	}
+
+	lines = append(lines, planExistence("hpa", manifests.HPA.Name, func() (metav1.Object, error) {
+		return clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace).Get(ctx, manifests.HPA.Name, metav1.GetOptions{})
+	})...)
+
+	networkPolicies := clientset.NetworkingV1().NetworkPolicies(namespace)
+	liveNetworkPolicy, err := networkPolicies.Get(ctx, manifests.NetworkPolicy.Name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		lines = append(lines, fmt.Sprintf("+ networkpolicy/%s will be created", manifests.NetworkPolicy.Name))
+	case err != nil:
+		return "", fmt.Errorf("failed to get network policy: %w", err)
+	default:
+		lines = append(lines, diffNetworkPolicy(liveNetworkPolicy, manifests.NetworkPolicy))
+	}
+
+	if manifests.Ingress != nil {
+		ingresses := clientset.NetworkingV1().Ingresses(namespace)
+		liveIngress, err := ingresses.Get(ctx, manifests.Ingress.Name, metav1.GetOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+			lines = append(lines, fmt.Sprintf("+ ingress/%s will be created", manifests.Ingress.Name))
+		case err != nil:
+			return "", fmt.Errorf("failed to get ingress: %w", err)
+		default:
+			lines = append(lines, diffIngress(liveIngress, manifests.Ingress))
+		}
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+func diffDeployment(live *appsv1.Deployment, desired *appsv1.Deployment) string {
+	liveImage := live.Spec.Template.Spec.Containers[0].Image
+	desiredImage := desired.Spec.Template.Spec.Containers[0].Image
+	liveReplicas := int32(0)
+	if live.Spec.Replicas != nil {
+		liveReplicas = *live.Spec.Replicas
+	}
+	desiredReplicas := *desired.Spec.Replicas
+
+	if liveImage == desiredImage && liveReplicas == desiredReplicas {
+		return fmt.Sprintf("= deployment/%s unchanged", desired.Name)
+	}
+	return fmt.Sprintf("~ deployment/%s image=%s->%s replicas=%d->%d",
+		desired.Name, liveImage, desiredImage, liveReplicas, desiredReplicas)
+}
+
+func diffService(live *corev1.Service, desired *corev1.Service) string {
+	if reflect.DeepEqual(live.Spec.Selector, desired.Spec.Selector) && reflect.DeepEqual(live.Spec.Ports, desired.Spec.Ports) {
+		return fmt.Sprintf("= service/%s unchanged", desired.Name)
+	}
+	return fmt.Sprintf("~ service/%s selector/ports will be updated", desired.Name)
+}
+
+func diffNetworkPolicy(live *networkingv1.NetworkPolicy, desired *networkingv1.NetworkPolicy) string {
+	if reflect.DeepEqual(live.Spec, desired.Spec) {
+		return fmt.Sprintf("= networkpolicy/%s unchanged", desired.Name)
+	}
+	return fmt.Sprintf("~ networkpolicy/%s rules will be updated", desired.Name)
+}
+
+func diffIngress(live *networkingv1.Ingress, desired *networkingv1.Ingress) string {
+	if reflect.DeepEqual(live.Spec, desired.Spec) {
+		return fmt.Sprintf("= ingress/%s unchanged", desired.Name)
+	}
+	return fmt.Sprintf("~ ingress/%s rules will be updated", desired.Name)
+}
+
+func planExistence(kind, name string, get func() (metav1.Object, error)) []string {
+	if _, err := get(); apierrors.IsNotFound(err) {
+		return []string{fmt.Sprintf("+ %s/%s will be created", kind, name)}
+	} else if err != nil {
+		return []string{fmt.Sprintf("? %s/%s could not be inspected: %v", kind, name, err)}
+	}
+	return []string{fmt.Sprintf("= %s/%s exists (spec not compared)", kind, name)}
+}
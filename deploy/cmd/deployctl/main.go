@@ -0,0 +1,78 @@
+// Command deployctl renders and applies a ServiceDeployment spec. It
+// replaces ./cicd/deploy.sh as the thing Goserv.DeployFromSpec execs
+// inside the pipeline container.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"goserv/deploy"
+)
+
+func main() {
+	specPath := flag.String("spec", "", "path to the ServiceDeployment spec (YAML or JSON)")
+	environment := flag.String("environment", "", "environment to deploy (selects the overlay and target)")
+	plan := flag.Bool("plan", false, "print a diff against the live cluster instead of applying")
+	kubeconfigPath := flag.String("kubeconfig", "", "path to the kubeconfig used to reach the target cluster")
+	flag.Parse()
+
+	if *specPath == "" || *environment == "" {
+		log.Fatal("-spec and -environment are required")
+	}
+
+	if err := run(*specPath, *environment, *kubeconfigPath, *plan); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(specPath, environment, kubeconfigPath string, plan bool) error {
+	data, err := os.ReadFile(specPath)
+	if err != nil {
+		return fmt.Errorf("failed to read spec: %w", err)
+	}
+
+	spec, err := deploy.ParseSpec(data)
+	if err != nil {
+		return err
+	}
+
+	spec = spec.ForEnvironment(environment)
+	target, err := spec.TargetFor(environment)
+	if err != nil {
+		return err
+	}
+
+	manifests, err := deploy.Render(spec, target)
+	if err != nil {
+		return err
+	}
+
+	kubeconfig, err := os.ReadFile(kubeconfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to read kubeconfig: %w", err)
+	}
+
+	clientset, err := deploy.NewClientset(kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	var output string
+	if plan {
+		output, err = deploy.Plan(ctx, clientset, target.Namespace, manifests)
+	} else {
+		output, err = deploy.Apply(ctx, clientset, target.Namespace, manifests)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(output)
+	return nil
+}
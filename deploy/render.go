@@ -0,0 +1,266 @@
+package deploy
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// Manifests is the set of Kubernetes objects rendered for one target.
+// Ingress is nil unless the spec sets spec.ingress.host.
+type Manifests struct {
+	Deployment    *appsv1.Deployment
+	Service       *corev1.Service
+	HPA           *autoscalingv2.HorizontalPodAutoscaler
+	NetworkPolicy *networkingv1.NetworkPolicy
+	Ingress       *networkingv1.Ingress
+}
+
+const servicePort = 8080
+
+// defaultCPUUtilizationPercentage is the target average CPU utilization
+// the HPA scales on; the spec has no field to change it. The
+// autoscaling/v2 API rejects an HPA with no metrics, so Render always
+// sets one.
+const defaultCPUUtilizationPercentage = int32(80)
+
+// Render turns a spec + target into concrete Kubernetes manifests. The
+// caller is expected to have already applied the environment overlay via
+// ServiceDeployment.ForEnvironment. It returns an error if the spec's
+// resource quantities (cpu/memory) aren't valid Kubernetes quantities.
+func Render(spec *ServiceDeployment, target Target) (*Manifests, error) {
+	labels := map[string]string{"app": spec.Metadata.Name}
+
+	deployment, err := renderDeployment(spec, target, labels)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Manifests{
+		Deployment:    deployment,
+		Service:       renderService(spec, target, labels),
+		HPA:           renderHPA(spec, target),
+		NetworkPolicy: renderNetworkPolicy(spec, target, labels),
+		Ingress:       renderIngress(spec, target, labels),
+	}, nil
+}
+
+func renderDeployment(spec *ServiceDeployment, target Target, labels map[string]string) (*appsv1.Deployment, error) {
+	env := make([]corev1.EnvVar, 0, len(spec.Spec.Env))
+	for k, v := range spec.Spec.Env {
+		env = append(env, corev1.EnvVar{Name: k, Value: v})
+	}
+
+	resources, err := renderResources(spec.Spec.Resources)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render resources: %w", err)
+	}
+
+	return &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: spec.Metadata.Name, Namespace: target.Namespace, Labels: labels},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &spec.Spec.Replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:      spec.Metadata.Name,
+						Image:     spec.Spec.Image,
+						Env:       env,
+						Ports:     []corev1.ContainerPort{{ContainerPort: servicePort}},
+						Resources: resources,
+					}},
+				},
+			},
+		},
+	}, nil
+}
+
+func renderResources(r ResourceRequirements) (corev1.ResourceRequirements, error) {
+	toList := func(l ResourceList) (corev1.ResourceList, error) {
+		list := corev1.ResourceList{}
+		if l.CPU != "" {
+			q, err := resourceQuantity(l.CPU)
+			if err != nil {
+				return nil, fmt.Errorf("invalid cpu quantity %q: %w", l.CPU, err)
+			}
+			list[corev1.ResourceCPU] = q
+		}
+		if l.Memory != "" {
+			q, err := resourceQuantity(l.Memory)
+			if err != nil {
+				return nil, fmt.Errorf("invalid memory quantity %q: %w", l.Memory, err)
+			}
+			list[corev1.ResourceMemory] = q
+		}
+		return list, nil
+	}
+
+	requests, err := toList(r.Requests)
+	if err != nil {
+		return corev1.ResourceRequirements{}, err
+	}
+	limits, err := toList(r.Limits)
+	if err != nil {
+		return corev1.ResourceRequirements{}, err
+	}
+
+	return corev1.ResourceRequirements{
+		Requests: requests,
+		Limits:   limits,
+	}, nil
+}
+
+func renderService(spec *ServiceDeployment, target Target, labels map[string]string) *corev1.Service {
+	return &corev1.Service{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+		ObjectMeta: metav1.ObjectMeta{Name: spec.Metadata.Name, Namespace: target.Namespace, Labels: labels},
+		Spec: corev1.ServiceSpec{
+			Selector: labels,
+			Ports: []corev1.ServicePort{{
+				Port:       servicePort,
+				TargetPort: intstr.FromInt(servicePort),
+			}},
+		},
+	}
+}
+
+func renderHPA(spec *ServiceDeployment, target Target) *autoscalingv2.HorizontalPodAutoscaler {
+	// The schema allows replicas: 0 (e.g. to scale a target down), but the
+	// Kubernetes API rejects an HPA with MinReplicas < 1, so floor it here.
+	minReplicas := spec.Spec.Replicas
+	if minReplicas < 1 {
+		minReplicas = 1
+	}
+	maxReplicas := spec.Spec.Replicas * 3
+	if maxReplicas < minReplicas {
+		maxReplicas = minReplicas
+	}
+
+	cpuTarget := defaultCPUUtilizationPercentage
+
+	return &autoscalingv2.HorizontalPodAutoscaler{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "autoscaling/v2", Kind: "HorizontalPodAutoscaler"},
+		ObjectMeta: metav1.ObjectMeta{Name: spec.Metadata.Name, Namespace: target.Namespace},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       spec.Metadata.Name,
+			},
+			MinReplicas: &minReplicas,
+			MaxReplicas: maxReplicas,
+			Metrics: []autoscalingv2.MetricSpec{{
+				Type: autoscalingv2.ResourceMetricSourceType,
+				Resource: &autoscalingv2.ResourceMetricSource{
+					Name: corev1.ResourceCPU,
+					Target: autoscalingv2.MetricTarget{
+						Type:               autoscalingv2.UtilizationMetricType,
+						AverageUtilization: &cpuTarget,
+					},
+				},
+			}},
+		},
+	}
+}
+
+// renderNetworkPolicy locks the workload down to ingress on servicePort
+// plus, when the spec lists dependencies, egress to pods labeled
+// app=<dependency> so the workload can still reach what it depends on.
+func renderNetworkPolicy(spec *ServiceDeployment, target Target, labels map[string]string) *networkingv1.NetworkPolicy {
+	policyTypes := []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}
+
+	np := &networkingv1.NetworkPolicy{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "networking.k8s.io/v1", Kind: "NetworkPolicy"},
+		ObjectMeta: metav1.ObjectMeta{Name: spec.Metadata.Name, Namespace: target.Namespace},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: labels},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{{
+				Ports: []networkingv1.NetworkPolicyPort{{
+					Port: portPtr(servicePort),
+				}},
+			}},
+		},
+	}
+
+	if len(spec.Spec.Dependencies) > 0 {
+		policyTypes = append(policyTypes, networkingv1.PolicyTypeEgress)
+		egress := make([]networkingv1.NetworkPolicyEgressRule, 0, len(spec.Spec.Dependencies))
+		for _, dep := range spec.Spec.Dependencies {
+			egress = append(egress, networkingv1.NetworkPolicyEgressRule{
+				To: []networkingv1.NetworkPolicyPeer{{
+					PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": dep}},
+				}},
+			})
+		}
+		np.Spec.Egress = egress
+	}
+
+	np.Spec.PolicyTypes = policyTypes
+	return np
+}
+
+// renderIngress renders an Ingress exposing the service on spec.ingress.host,
+// or nil if the spec doesn't set one.
+func renderIngress(spec *ServiceDeployment, target Target, labels map[string]string) *networkingv1.Ingress {
+	ing := spec.Spec.Ingress
+	if ing == nil || ing.Host == "" {
+		return nil
+	}
+
+	path := ing.Path
+	if path == "" {
+		path = "/"
+	}
+	pathType := networkingv1.PathTypePrefix
+
+	ingressSpec := networkingv1.IngressSpec{
+		Rules: []networkingv1.IngressRule{{
+			Host: ing.Host,
+			IngressRuleValue: networkingv1.IngressRuleValue{
+				HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{{
+						Path:     path,
+						PathType: &pathType,
+						Backend: networkingv1.IngressBackend{
+							Service: &networkingv1.IngressServiceBackend{
+								Name: spec.Metadata.Name,
+								Port: networkingv1.ServiceBackendPort{Number: servicePort},
+							},
+						},
+					}},
+				},
+			},
+		}},
+	}
+
+	if ing.TLS {
+		ingressSpec.TLS = []networkingv1.IngressTLS{{
+			Hosts:      []string{ing.Host},
+			SecretName: spec.Metadata.Name + "-tls",
+		}}
+	}
+
+	return &networkingv1.Ingress{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "networking.k8s.io/v1", Kind: "Ingress"},
+		ObjectMeta: metav1.ObjectMeta{Name: spec.Metadata.Name, Namespace: target.Namespace, Labels: labels},
+		Spec:       ingressSpec,
+	}
+}
+
+func resourceQuantity(s string) (resource.Quantity, error) {
+	return resource.ParseQuantity(s)
+}
+
+func portPtr(port int32) *intstr.IntOrString {
+	v := intstr.FromInt(int(port))
+	return &v
+}
@@ -0,0 +1,33 @@
+package deploy
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+//go:embed schema.json
+var schemaJSON []byte
+
+var compiledSchema = mustCompileSchema()
+
+func mustCompileSchema() *jsonschema.Schema {
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource("schema.json", bytes.NewReader(schemaJSON)); err != nil {
+		panic(fmt.Sprintf("deploy: failed to load embedded schema: %v", err))
+	}
+	return compiler.MustCompile("schema.json")
+}
+
+// ValidateSchema checks JSON-encoded spec data against the in-repo
+// ServiceDeployment schema.
+func ValidateSchema(jsonData []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(jsonData, &v); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	return compiledSchema.Validate(v)
+}
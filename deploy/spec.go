@@ -0,0 +1,134 @@
+// Package deploy renders Kubernetes manifests from a declarative
+// ServiceDeployment spec, replacing the shell-script deploy pipeline
+// with an auditable, typed deployment surface.
+package deploy
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/yaml"
+)
+
+// ServiceDeployment is the top-level intent document: "deploy this
+// image, this many replicas, with these resources, to these targets".
+type ServiceDeployment struct {
+	APIVersion string   `json:"apiVersion"`
+	Kind       string   `json:"kind"`
+	Metadata   Metadata `json:"metadata"`
+	Spec       Spec     `json:"spec"`
+}
+
+type Metadata struct {
+	Name string `json:"name"`
+}
+
+type Spec struct {
+	Image        string               `json:"image"`
+	Replicas     int32                `json:"replicas"`
+	Resources    ResourceRequirements `json:"resources"`
+	Env          map[string]string    `json:"env,omitempty"`
+	Dependencies []string             `json:"dependencies,omitempty"`
+	Ingress      *Ingress             `json:"ingress,omitempty"`
+	Targets      []Target             `json:"targets"`
+	Overlays     map[string]Overlay   `json:"overlays,omitempty"`
+}
+
+type ResourceRequirements struct {
+	Requests ResourceList `json:"requests"`
+	Limits   ResourceList `json:"limits"`
+}
+
+type ResourceList struct {
+	CPU    string `json:"cpu"`
+	Memory string `json:"memory"`
+}
+
+type Ingress struct {
+	Host string `json:"host"`
+	Path string `json:"path"`
+	TLS  bool   `json:"tls"`
+}
+
+type Target struct {
+	Cluster     string `json:"cluster"`
+	Namespace   string `json:"namespace"`
+	Environment string `json:"environment"`
+}
+
+// Overlay carries the fields an environment overlay is allowed to
+// override on the base Spec.
+type Overlay struct {
+	Image    string            `json:"image,omitempty"`
+	Replicas *int32            `json:"replicas,omitempty"`
+	Env      map[string]string `json:"env,omitempty"`
+}
+
+const expectedKind = "ServiceDeployment"
+
+// ParseSpec accepts either YAML or JSON, validates it against the
+// in-repo JSON Schema, and returns the decoded spec.
+func ParseSpec(data []byte) (*ServiceDeployment, error) {
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse spec: %w", err)
+	}
+
+	if err := ValidateSchema(jsonData); err != nil {
+		return nil, fmt.Errorf("spec failed schema validation: %w", err)
+	}
+
+	var spec ServiceDeployment
+	if err := yaml.Unmarshal(jsonData, &spec); err != nil {
+		return nil, fmt.Errorf("failed to decode spec: %w", err)
+	}
+
+	if spec.Kind != expectedKind {
+		return nil, fmt.Errorf("unexpected kind %q, want %q", spec.Kind, expectedKind)
+	}
+	if len(spec.Spec.Targets) == 0 {
+		return nil, fmt.Errorf("spec must declare at least one target")
+	}
+
+	return &spec, nil
+}
+
+// ForEnvironment returns a copy of the spec with the named overlay
+// applied on top of the base spec fields it's allowed to override. When
+// no overlay is declared for environment it returns s itself.
+func (s *ServiceDeployment) ForEnvironment(environment string) *ServiceDeployment {
+	overlay, ok := s.Spec.Overlays[environment]
+	if !ok {
+		return s
+	}
+
+	merged := *s
+	if overlay.Image != "" {
+		merged.Spec.Image = overlay.Image
+	}
+	if overlay.Replicas != nil {
+		merged.Spec.Replicas = *overlay.Replicas
+	}
+	if len(overlay.Env) > 0 {
+		env := make(map[string]string, len(s.Spec.Env)+len(overlay.Env))
+		for k, v := range s.Spec.Env {
+			env[k] = v
+		}
+		for k, v := range overlay.Env {
+			env[k] = v
+		}
+		merged.Spec.Env = env
+	}
+
+	return &merged
+}
+
+// TargetFor returns the target matching the given environment, or an
+// error if none is declared.
+func (s *ServiceDeployment) TargetFor(environment string) (Target, error) {
+	for _, t := range s.Spec.Targets {
+		if t.Environment == environment {
+			return t, nil
+		}
+	}
+	return Target{}, fmt.Errorf("no target declared for environment %q", environment)
+}
@@ -0,0 +1,73 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"goserv/testutil/service"
+)
+
+func TestReadyHandler_FailsWhenDependencyDown(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	dependency := service.NewFakeDependency()
+	dependency.Close() // closed before goserv ever calls it, so the check fails
+
+	svc, err := service.Start(ctx, service.Options{
+		ServiceName:   "goserv-it",
+		DependencyURL: dependency.URL(),
+	})
+	if err != nil {
+		t.Fatalf("failed to start goserv: %v", err)
+	}
+	defer svc.Stop()
+
+	resp, err := getWithRetry(ctx, svc.URL()+"/ready")
+	if err != nil {
+		t.Fatalf("failed to call /ready: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 with a dead dependency, got %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Status string `json:"status"`
+		Checks []struct {
+			Name   string `json:"name"`
+			Status string `json:"status"`
+		} `json:"checks"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response body: %v", err)
+	}
+
+	if body.Status != "fail" {
+		t.Errorf("expected overall status fail, got %q", body.Status)
+	}
+}
+
+// getWithRetry polls url until it gets a response or ctx expires, since
+// the service may still be starting up.
+func getWithRetry(ctx context.Context, url string) (*http.Response, error) {
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		resp, err := http.Get(url)
+		if err == nil {
+			return resp, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"goserv/testutil/service"
+)
+
+func TestRootHandler_ReturnsServiceMetadata(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	svc, err := service.Start(ctx, service.Options{ServiceName: "goserv-it"})
+	if err != nil {
+		t.Fatalf("failed to start goserv: %v", err)
+	}
+	defer svc.Stop()
+
+	if err := svc.WaitReady(ctx); err != nil {
+		t.Fatalf("service never became ready: %v", err)
+	}
+
+	resp, err := svc.CallRoot()
+	if err != nil {
+		t.Fatalf("failed to call root: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	var body map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response body: %v", err)
+	}
+
+	if body["service_name"] != "goserv-it" {
+		t.Errorf("expected service_name %q, got %v", "goserv-it", body["service_name"])
+	}
+}
+
+func TestRootHandler_PropagatesDependencyHeaders(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	dependency := service.NewFakeDependency()
+	defer dependency.Close()
+	dependency.SetHeader("X-Upstream-Version", "v1.2.3")
+
+	svc, err := service.Start(ctx, service.Options{
+		ServiceName:   "goserv-it",
+		DependencyURL: dependency.URL(),
+	})
+	if err != nil {
+		t.Fatalf("failed to start goserv: %v", err)
+	}
+	defer svc.Stop()
+
+	if err := svc.WaitReady(ctx); err != nil {
+		t.Fatalf("service never became ready: %v", err)
+	}
+
+	resp, err := svc.CallRoot()
+	if err != nil {
+		t.Fatalf("failed to call root: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		DependencyHeaders map[string][]string `json:"dependency_headers"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response body: %v", err)
+	}
+
+	got := body.DependencyHeaders["X-Upstream-Version"]
+	if len(got) != 1 || got[0] != "v1.2.3" {
+		t.Errorf("expected dependency_headers[X-Upstream-Version] = [v1.2.3], got %v", got)
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CheckStatus mirrors the pass/warn/fail vocabulary from the IETF
+// health-check response format draft.
+type CheckStatus string
+
+const (
+	StatusPass CheckStatus = "pass"
+	StatusWarn CheckStatus = "warn"
+	StatusFail CheckStatus = "fail"
+)
+
+// HealthChecker is a single dependency or subsystem that readiness can
+// be computed from.
+type HealthChecker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+// CheckResult is the outcome of running one HealthChecker.
+type CheckResult struct {
+	Name      string      `json:"name"`
+	Status    CheckStatus `json:"status"`
+	LatencyMS int64       `json:"latency_ms"`
+	Error     string      `json:"error,omitempty"`
+}
+
+// ReadinessResponse is the JSON body served by /ready.
+type ReadinessResponse struct {
+	Status CheckStatus   `json:"status"`
+	Checks []CheckResult `json:"checks"`
+}
+
+type registeredChecker struct {
+	checker  HealthChecker
+	optional bool
+}
+
+type cachedResult struct {
+	result  CheckResult
+	expires time.Time
+}
+
+// HealthRegistry runs a set of HealthCheckers and caches their results
+// for a configurable TTL so readiness probes don't hammer dependencies.
+type HealthRegistry struct {
+	ttl time.Duration
+
+	mu       sync.Mutex
+	checkers []registeredChecker
+	cache    map[string]cachedResult
+}
+
+// NewHealthRegistry creates a registry that caches each check's result
+// for the given TTL.
+func NewHealthRegistry(ttl time.Duration) *HealthRegistry {
+	return &HealthRegistry{
+		ttl:   ttl,
+		cache: make(map[string]cachedResult),
+	}
+}
+
+// Register adds a checker to the registry. An optional checker that
+// fails downgrades overall status to "warn" instead of "fail".
+func (r *HealthRegistry) Register(checker HealthChecker, optional bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checkers = append(r.checkers, registeredChecker{checker: checker, optional: optional})
+}
+
+// Evaluate runs (or serves cached results for) every registered checker
+// and aggregates them into a single readiness response.
+func (r *HealthRegistry) Evaluate(ctx context.Context) ReadinessResponse {
+	r.mu.Lock()
+	checkers := make([]registeredChecker, len(r.checkers))
+	copy(checkers, r.checkers)
+	r.mu.Unlock()
+
+	resp := ReadinessResponse{
+		Status: StatusPass,
+		Checks: make([]CheckResult, 0, len(checkers)),
+	}
+
+	for _, rc := range checkers {
+		result := r.run(ctx, rc.checker)
+		resp.Checks = append(resp.Checks, result)
+
+		switch {
+		case result.Status == StatusFail && !rc.optional:
+			resp.Status = StatusFail
+		case result.Status == StatusFail && rc.optional:
+			if resp.Status != StatusFail {
+				resp.Status = StatusWarn
+			}
+		case result.Status == StatusWarn && resp.Status == StatusPass:
+			resp.Status = StatusWarn
+		}
+	}
+
+	return resp
+}
+
+// HTTPStatus maps an aggregated readiness status to the status code the
+// /ready handler should return.
+func (r ReadinessResponse) HTTPStatus() int {
+	if r.Status == StatusFail {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusOK
+}
+
+func (r *HealthRegistry) run(ctx context.Context, checker HealthChecker) CheckResult {
+	r.mu.Lock()
+	cached, ok := r.cache[checker.Name()]
+	r.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.result
+	}
+
+	start := time.Now()
+	err := checker.Check(ctx)
+	latency := time.Since(start)
+
+	result := CheckResult{
+		Name:      checker.Name(),
+		Status:    StatusPass,
+		LatencyMS: latency.Milliseconds(),
+	}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+
+	r.mu.Lock()
+	r.cache[checker.Name()] = cachedResult{result: result, expires: time.Now().Add(r.ttl)}
+	r.mu.Unlock()
+
+	return result
+}
+
+// DependencyHealthChecker probes config.DependencyURL with a short-lived
+// GET request. It is the registry's default checker, registered whenever
+// a dependency URL is configured.
+type DependencyHealthChecker struct {
+	URL     string
+	Timeout time.Duration
+}
+
+func (c *DependencyHealthChecker) Name() string {
+	return "dependency"
+}
+
+func (c *DependencyHealthChecker) Check(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.URL, nil)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: c.Timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return &dependencyStatusError{statusCode: resp.StatusCode}
+	}
+	return nil
+}
+
+type dependencyStatusError struct {
+	statusCode int
+}
+
+func (e *dependencyStatusError) Error() string {
+	return http.StatusText(e.statusCode)
+}
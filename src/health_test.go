@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeChecker struct {
+	name  string
+	err   error
+	calls int
+}
+
+func (c *fakeChecker) Name() string { return c.name }
+
+func (c *fakeChecker) Check(ctx context.Context) error {
+	c.calls++
+	return c.err
+}
+
+func TestHealthRegistry_OptionalFailureDowngradesToWarn(t *testing.T) {
+	registry := NewHealthRegistry(time.Minute)
+	registry.Register(&fakeChecker{name: "cache", err: errors.New("boom")}, true)
+
+	resp := registry.Evaluate(context.Background())
+
+	if resp.Status != StatusWarn {
+		t.Fatalf("expected overall status warn, got %q", resp.Status)
+	}
+}
+
+func TestHealthRegistry_RequiredFailureFails(t *testing.T) {
+	registry := NewHealthRegistry(time.Minute)
+	registry.Register(&fakeChecker{name: "db", err: errors.New("boom")}, false)
+
+	resp := registry.Evaluate(context.Background())
+
+	if resp.Status != StatusFail {
+		t.Fatalf("expected overall status fail, got %q", resp.Status)
+	}
+}
+
+func TestHealthRegistry_CacheHitsDontReinvokeCheck(t *testing.T) {
+	registry := NewHealthRegistry(time.Minute)
+	checker := &fakeChecker{name: "dependency"}
+	registry.Register(checker, false)
+
+	registry.Evaluate(context.Background())
+	registry.Evaluate(context.Background())
+
+	if checker.calls != 1 {
+		t.Fatalf("expected Check to run once within the TTL, got %d calls", checker.calls)
+	}
+}
+
+func TestHealthRegistry_CacheExpiryReinvokesCheck(t *testing.T) {
+	registry := NewHealthRegistry(10 * time.Millisecond)
+	checker := &fakeChecker{name: "dependency"}
+	registry.Register(checker, false)
+
+	registry.Evaluate(context.Background())
+	time.Sleep(20 * time.Millisecond)
+	registry.Evaluate(context.Background())
+
+	if checker.calls != 2 {
+		t.Fatalf("expected Check to run again once the cache entry expired, got %d calls", checker.calls)
+	}
+}
@@ -1,22 +1,33 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Config struct {
-	ServiceName    string
-	ServiceVersion string
-	DependencyURL  string
-	Port           string
+	ServiceName     string
+	ServiceVersion  string
+	DependencyURL   string
+	Port            string
+	ShutdownTimeout time.Duration
+	HealthCacheTTL  time.Duration
+	OTLPEndpoint    string
+	MetricsPort     string
 }
 
 type Response struct {
@@ -26,12 +37,26 @@ type Response struct {
 	InstanceUUID      string              `json:"instance_uuid"`
 	DependencyHeaders map[string][]string `json:"dependency_headers,omitempty"`
 	Timestamp         string              `json:"timestamp"`
+	TraceID           string              `json:"trace_id,omitempty"`
 }
 
 var (
 	config       Config
 	instanceUUID string
 	version      = "dev" // Set via ldflags at build time
+
+	// shuttingDown flips to 1 as soon as the server starts draining, so
+	// readiness probes can fail fast while liveness stays up until the
+	// listener actually stops.
+	shuttingDown int32
+
+	healthRegistry *HealthRegistry
+)
+
+const (
+	defaultShutdownTimeout = 10 * time.Second
+	defaultHealthCacheTTL  = 5 * time.Second
+	dependencyCheckTimeout = 2 * time.Second
 )
 
 func init() {
@@ -40,10 +65,22 @@ func init() {
 
 	// Load configuration from environment variables
 	config = Config{
-		ServiceName:    getEnv("SERVICE_NAME", "goserv"),
-		ServiceVersion: getEnv("SERVICE_VERSION", version),
-		DependencyURL:  getEnv("DEPENDENCY_URL", ""),
-		Port:           getEnv("PORT", "8080"),
+		ServiceName:     getEnv("SERVICE_NAME", "goserv"),
+		ServiceVersion:  getEnv("SERVICE_VERSION", version),
+		DependencyURL:   getEnv("DEPENDENCY_URL", ""),
+		Port:            getEnv("PORT", "8080"),
+		ShutdownTimeout: getEnvDuration("SHUTDOWN_TIMEOUT", defaultShutdownTimeout),
+		HealthCacheTTL:  getEnvDuration("HEALTH_CACHE_TTL", defaultHealthCacheTTL),
+		OTLPEndpoint:    getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", ""),
+		MetricsPort:     getEnv("METRICS_PORT", ""),
+	}
+
+	healthRegistry = NewHealthRegistry(config.HealthCacheTTL)
+	if config.DependencyURL != "" {
+		healthRegistry.Register(&DependencyHealthChecker{
+			URL:     config.DependencyURL,
+			Timeout: dependencyCheckTimeout,
+		}, false)
 	}
 }
 
@@ -54,6 +91,20 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid %s %q, using default %s: %v", key, value, defaultValue, err)
+		return defaultValue
+	}
+	return d
+}
+
 func getOutboundIP() string {
 	conn, err := net.Dial("udp", "8.8.8.8:80")
 	if err != nil {
@@ -65,16 +116,22 @@ func getOutboundIP() string {
 	return localAddr.IP.String()
 }
 
-func callDependency() (map[string][]string, error) {
+func callDependency(ctx context.Context) (map[string][]string, error) {
 	if config.DependencyURL == "" {
 		return nil, nil
 	}
 
 	client := &http.Client{
-		Timeout: 5 * time.Second,
+		Timeout:   5 * time.Second,
+		Transport: otelhttp.NewTransport(http.DefaultTransport),
 	}
 
-	resp, err := client.Get(config.DependencyURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, config.DependencyURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build dependency request: %w", err)
+	}
+
+	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to call dependency: %w", err)
 	}
@@ -97,9 +154,13 @@ func rootHandler(w http.ResponseWriter, r *http.Request) {
 		Timestamp:      time.Now().UTC().Format(time.RFC3339),
 	}
 
+	if span := trace.SpanContextFromContext(r.Context()); span.HasTraceID() {
+		response.TraceID = span.TraceID().String()
+	}
+
 	// Call dependency if configured
 	if config.DependencyURL != "" {
-		headers, err := callDependency()
+		headers, err := callDependency(r.Context())
 		if err != nil {
 			log.Printf("Error calling dependency: %v", err)
 			// Continue without dependency headers
@@ -124,16 +185,57 @@ func healthHandler(w http.ResponseWriter, r *http.Request) {
 
 func readyHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{"status": "ready"})
+
+	if atomic.LoadInt32(&shuttingDown) == 1 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(ReadinessResponse{
+			Status: StatusFail,
+			Checks: []CheckResult{{Name: "shutdown", Status: StatusFail, Error: "server is draining"}},
+		})
+		return
+	}
+
+	resp := healthRegistry.Evaluate(r.Context())
+	w.WriteHeader(resp.HTTPStatus())
+	json.NewEncoder(w).Encode(resp)
 }
 
 func main() {
-	http.HandleFunc("/", rootHandler)
-	http.HandleFunc("/health", healthHandler)
-	http.HandleFunc("/ready", readyHandler)
+	obs, err := initObservability(context.Background(), config, instanceUUID)
+	if err != nil {
+		log.Fatalf("Failed to initialize observability: %v", err)
+	}
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := obs.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Error shutting down tracer provider: %v", err)
+		}
+	}()
+
+	mux := http.NewServeMux()
+	mux.Handle("/", obs.Middleware("/", rootHandler))
+	mux.Handle("/health", obs.Middleware("/health", healthHandler))
+	mux.Handle("/ready", obs.Middleware("/ready", readyHandler))
+
+	if config.MetricsPort == "" {
+		mux.Handle("/metrics", obs.MetricsHandler())
+	} else {
+		go func() {
+			metricsAddr := ":" + config.MetricsPort
+			log.Printf("Serving /metrics on %s", metricsAddr)
+			if err := http.ListenAndServe(metricsAddr, obs.MetricsHandler()); err != nil {
+				log.Printf("Metrics server stopped: %v", err)
+			}
+		}()
+	}
 
 	addr := ":" + config.Port
+	srv := &http.Server{
+		Addr:    addr,
+		Handler: mux,
+	}
+
 	log.Printf("Starting %s v%s on %s (Instance: %s)",
 		config.ServiceName, config.ServiceVersion, addr, instanceUUID)
 
@@ -141,7 +243,31 @@ func main() {
 		log.Printf("Dependency URL configured: %s", config.DependencyURL)
 	}
 
-	if err := http.ListenAndServe(addr, nil); err != nil {
-		log.Fatalf("Server failed to start: %v", err)
+	serverErrors := make(chan error, 1)
+	go func() {
+		serverErrors <- srv.ListenAndServe()
+	}()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case err := <-serverErrors:
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Server failed to start: %v", err)
+		}
+	case sig := <-stop:
+		log.Printf("Received %s, starting graceful shutdown (timeout: %s)", sig, config.ShutdownTimeout)
+		atomic.StoreInt32(&shuttingDown, 1)
+
+		ctx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+		defer cancel()
+
+		if err := srv.Shutdown(ctx); err != nil {
+			log.Printf("Graceful shutdown failed, forcing close: %v", err)
+			srv.Close()
+		}
 	}
+
+	log.Printf("%s stopped", config.ServiceName)
 }
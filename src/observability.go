@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	sdkresource "go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Observability wires up tracing and metrics for the HTTP service: an
+// OTel TracerProvider exporting spans over OTLP/gRPC, and a Prometheus
+// registry tracking request duration, status codes, and in-flight
+// requests per handler.
+type Observability struct {
+	TracerProvider *sdktrace.TracerProvider
+	Registry       *prometheus.Registry
+
+	requestDuration *prometheus.HistogramVec
+	requestsTotal   *prometheus.CounterVec
+	inFlight        *prometheus.GaugeVec
+}
+
+// initObservability builds the TracerProvider and Prometheus registry
+// for this instance. If OTEL_EXPORTER_OTLP_ENDPOINT is unset, spans are
+// still recorded but never exported, so the rest of the request path
+// behaves identically in environments without a collector.
+func initObservability(ctx context.Context, cfg Config, instanceUUID string) (*Observability, error) {
+	res, err := sdkresource.New(ctx,
+		sdkresource.WithAttributes(
+			semconv.ServiceName(cfg.ServiceName),
+			semconv.ServiceVersion(cfg.ServiceVersion),
+			semconv.ServiceInstanceID(instanceUUID),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build otel resource: %w", err)
+	}
+
+	tpOpts := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+
+	if endpoint := cfg.OTLPEndpoint; endpoint != "" {
+		exporter, err := otlptracegrpc.New(ctx,
+			otlptracegrpc.WithEndpoint(endpoint),
+			otlptracegrpc.WithInsecure(),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create otlp exporter: %w", err)
+		}
+		tpOpts = append(tpOpts, sdktrace.WithBatcher(exporter))
+	}
+
+	tp := sdktrace.NewTracerProvider(tpOpts...)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	registry := prometheus.NewRegistry()
+	requestDuration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "http_request_duration_seconds",
+		Help: "Duration of HTTP requests in seconds.",
+	}, []string{"path", "status"})
+	requestsTotal := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Total number of HTTP requests.",
+	}, []string{"path", "status"})
+	inFlight := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "http_requests_in_flight",
+		Help: "Number of HTTP requests currently being served.",
+	}, []string{"path"})
+
+	registry.MustRegister(requestDuration, requestsTotal, inFlight)
+
+	return &Observability{
+		TracerProvider:  tp,
+		Registry:        registry,
+		requestDuration: requestDuration,
+		requestsTotal:   requestsTotal,
+		inFlight:        inFlight,
+	}, nil
+}
+
+// Shutdown flushes any pending spans and releases exporter resources.
+func (o *Observability) Shutdown(ctx context.Context) error {
+	return o.TracerProvider.Shutdown(ctx)
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Middleware wraps a handler with an OTel span, request duration/count
+// metrics, and an in-flight gauge, labeled by path and response status.
+func (o *Observability) Middleware(path string, next http.HandlerFunc) http.Handler {
+	instrumented := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		o.inFlight.WithLabelValues(path).Inc()
+		defer o.inFlight.WithLabelValues(path).Dec()
+
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		if span := trace.SpanContextFromContext(r.Context()); span.HasTraceID() {
+			rec.Header().Set("X-Trace-Id", span.TraceID().String())
+		}
+
+		next.ServeHTTP(rec, r)
+
+		status := strconv.Itoa(rec.status)
+		o.requestDuration.WithLabelValues(path, status).Observe(time.Since(start).Seconds())
+		o.requestsTotal.WithLabelValues(path, status).Inc()
+	})
+
+	return otelhttp.NewHandler(instrumented, path)
+}
+
+// MetricsHandler serves the Prometheus registry in the standard
+// exposition format.
+func (o *Observability) MetricsHandler() http.Handler {
+	return promhttp.HandlerFor(o.Registry, promhttp.HandlerOpts{Registry: o.Registry})
+}
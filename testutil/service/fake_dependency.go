@@ -0,0 +1,70 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+)
+
+// FakeDependency is an in-process stand-in for whatever DEPENDENCY_URL
+// points at, letting tests control the headers and latency goserv's
+// DependencyHeaders path sees.
+type FakeDependency struct {
+	server *httptest.Server
+
+	mu      sync.Mutex
+	headers http.Header
+	latency time.Duration
+}
+
+// NewFakeDependency starts a fake dependency server. Call Close when done.
+func NewFakeDependency() *FakeDependency {
+	fd := &FakeDependency{headers: make(http.Header)}
+	fd.server = httptest.NewServer(http.HandlerFunc(fd.handle))
+	return fd
+}
+
+// SetHeader injects a response header the next request(s) will return.
+func (f *FakeDependency) SetHeader(key, value string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.headers.Set(key, value)
+}
+
+// SetLatency delays every subsequent response by d, to exercise timeouts
+// and slow-dependency handling.
+func (f *FakeDependency) SetLatency(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.latency = d
+}
+
+// URL returns the address tests should set DEPENDENCY_URL to.
+func (f *FakeDependency) URL() string {
+	return f.server.URL
+}
+
+// Close shuts down the fake dependency server.
+func (f *FakeDependency) Close() {
+	f.server.Close()
+}
+
+func (f *FakeDependency) handle(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	latency := f.latency
+	headers := f.headers.Clone()
+	f.mu.Unlock()
+
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+
+	for key, values := range headers {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+}